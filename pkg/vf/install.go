@@ -0,0 +1,176 @@
+package vf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Code-Hex/vz/v3"
+	"github.com/crc-org/vfkit/pkg/config"
+)
+
+const (
+	macOSAuxImageName          = "AuxiliaryStorage"
+	macOSHardwareModelName     = "HardwareModel"
+	macOSMachineIdentifierName = "MachineIdentifier"
+	macOSDiskImageName         = "disk.img"
+	macOSConfigName            = "config.json"
+)
+
+// InstallMacOS installs a macOS guest from the restore image at ipswPath,
+// creating the auxiliary storage, hardware model and machine identifier
+// files vfkit needs to boot the resulting guest in bundleDir, along with a
+// config.json holding the equivalent config.MacOSBootloader so a CLI or the
+// REST API can pick the guest back up later. It returns that same
+// MacOSBootloader.
+//
+// progress, if non-nil, receives the fraction of the installation completed
+// so far (0..1) and is closed when InstallMacOS returns. The installation is
+// cancellable through ctx. On any failure or cancellation, files already
+// created in bundleDir are removed.
+func InstallMacOS(ctx context.Context, ipswPath string, bundleDir string, progress chan<- float64) (bootloader *config.MacOSBootloader, err error) {
+	if progress != nil {
+		defer close(progress)
+	}
+
+	var createdFiles []string
+	defer func() {
+		if err != nil {
+			for _, path := range createdFiles {
+				_ = os.Remove(path)
+			}
+		}
+	}()
+
+	restoreImage, err := vz.LoadMacOSRestoreImageFromPath(ipswPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load restore image %s: %w", ipswPath, err)
+	}
+
+	requirements := restoreImage.MostFeaturefulSupportedConfiguration()
+	if requirements == nil {
+		return nil, fmt.Errorf("restore image %s has no configuration supported on this host", ipswPath)
+	}
+	hardwareModel := requirements.HardwareModel()
+
+	auxImagePath := filepath.Join(bundleDir, macOSAuxImageName)
+	hardwareModelPath := filepath.Join(bundleDir, macOSHardwareModelName)
+	machineIdentifierPath := filepath.Join(bundleDir, macOSMachineIdentifierName)
+	diskImagePath := filepath.Join(bundleDir, macOSDiskImageName)
+	configPath := filepath.Join(bundleDir, macOSConfigName)
+
+	auxStorage, err := vz.NewMacAuxiliaryStorage(auxImagePath, vz.WithCreatingMacAuxiliaryStorage(hardwareModel))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create auxiliary storage: %w", err)
+	}
+	createdFiles = append(createdFiles, auxImagePath)
+
+	if err := hardwareModel.DataRepresentation().Write(hardwareModelPath); err != nil {
+		return nil, fmt.Errorf("failed to write hardware model: %w", err)
+	}
+	createdFiles = append(createdFiles, hardwareModelPath)
+
+	machineIdentifier, err := vz.NewMacMachineIdentifier()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create machine identifier: %w", err)
+	}
+	if err := machineIdentifier.DataRepresentation().Write(machineIdentifierPath); err != nil {
+		return nil, fmt.Errorf("failed to write machine identifier: %w", err)
+	}
+	createdFiles = append(createdFiles, machineIdentifierPath)
+
+	platformConfig, err := vz.NewMacPlatformConfiguration(
+		vz.WithMacMachineIdentifier(machineIdentifier),
+		vz.WithMacHardwareModel(hardwareModel),
+		vz.WithMacAuxiliaryStorage(auxStorage),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mac platform configuration: %w", err)
+	}
+
+	vzBootloader, err := vz.NewMacOSBootLoader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create macOS bootloader: %w", err)
+	}
+
+	installConfig, err := vz.NewVirtualMachineConfiguration(vzBootloader, requirements.MinimumSupportedCPUCount(), requirements.MinimumSupportedMemorySize())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create installer configuration: %w", err)
+	}
+	installConfig.SetPlatformVirtualMachineConfiguration(platformConfig)
+
+	diskAttachment, err := vz.NewDiskImageStorageDeviceAttachment(diskImagePath, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open disk image %s for installation: %w", diskImagePath, err)
+	}
+
+	storageDeviceConfig, err := vz.NewVirtioBlockDeviceConfiguration(diskAttachment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage device for installation: %w", err)
+	}
+	installConfig.SetStorageDevicesVirtualMachineConfiguration([]vz.StorageDeviceConfiguration{storageDeviceConfig})
+
+	valid, err := installConfig.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate installer configuration: %w", err)
+	}
+	if !valid {
+		return nil, fmt.Errorf("invalid installer configuration")
+	}
+
+	vzVM, err := vz.NewVirtualMachine(installConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create installer virtual machine: %w", err)
+	}
+
+	installer, err := vz.NewMacOSInstaller(vzVM, restoreImage.Path())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create macOS installer: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		installer.Cancel()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case frac, ok := <-installer.FractionCompleted():
+			if !ok {
+				// installer.FractionCompleted channel closes once the
+				// installation has finished or failed.
+				if err := installer.Err(); err != nil {
+					return nil, fmt.Errorf("macOS installation failed: %w", err)
+				}
+
+				bootloader := &config.MacOSBootloader{
+					MachineIdentifierPath: machineIdentifierPath,
+					HardwareModelPath:     hardwareModelPath,
+					AuxImagePath:          auxImagePath,
+				}
+
+				configJSON, err := json.MarshalIndent(bootloader, "", "  ")
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal bootloader config: %w", err)
+				}
+				if err := os.WriteFile(configPath, configJSON, 0644); err != nil {
+					return nil, fmt.Errorf("failed to write %s: %w", configPath, err)
+				}
+				createdFiles = append(createdFiles, configPath)
+
+				return bootloader, nil
+			}
+			if progress != nil {
+				select {
+				case progress <- frac:
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package vf
+
+import (
+	"fmt"
+	"os"
+)
+
+// macOSStateFileName is the snapshot file Start looks for next to the
+// bundle's other macOS guest artifacts. SaveState writes to whatever path it
+// is given, so a snapshot is auto-restored only if it was saved there.
+const macOSStateFileName = "vm.state"
+
+// Pause suspends guest execution without tearing down the virtual machine,
+// keeping all of its state in memory so Resume can continue exactly where
+// it left off. Only macOS guests are suspendable in Virtualization.framework
+// today; Linux guests return an error.
+func (vm *VirtualMachine) Pause() error {
+	if PlatformType != "macos" {
+		return fmt.Errorf("pause is only supported for macOS guests, not %q", PlatformType)
+	}
+	return vm.VirtualMachine.Pause()
+}
+
+// Resume continues a virtual machine previously suspended with Pause.
+func (vm *VirtualMachine) Resume() error {
+	if PlatformType != "macos" {
+		return fmt.Errorf("resume is only supported for macOS guests, not %q", PlatformType)
+	}
+	return vm.VirtualMachine.Resume()
+}
+
+// SaveState pauses the virtual machine (if it isn't already) and writes its
+// full state to path, so it can later be restored with RestoreState instead
+// of cold-booting. saveMachineState requires the virtual machine to already
+// be paused, which is why this method pauses first rather than just
+// forwarding to it.
+func (vm *VirtualMachine) SaveState(path string) error {
+	if PlatformType != "macos" {
+		return fmt.Errorf("save state is only supported for macOS guests, not %q", PlatformType)
+	}
+	if vm.VirtualMachine.CanPause() {
+		if err := vm.VirtualMachine.Pause(); err != nil {
+			return fmt.Errorf("failed to pause before saving state: %w", err)
+		}
+	}
+	return vm.VirtualMachine.SaveMachineStateToPath(path)
+}
+
+// RestoreState restores the virtual machine from a snapshot previously
+// written by SaveState. vm's underlying vz.VirtualMachine must already exist
+// to restore into; Start creates it and calls RestoreState in place of a cold
+// boot, before the virtual machine has ever been started.
+func (vm *VirtualMachine) RestoreState(path string) error {
+	if PlatformType != "macos" {
+		return fmt.Errorf("restore state is only supported for macOS guests, not %q", PlatformType)
+	}
+	return vm.VirtualMachine.RestoreMachineStateFromPath(path)
+}
+
+// Stop stops the virtual machine through vz's Stop, without waiting for the
+// guest to shut down. Any auto-restore snapshot left next to the bundle is
+// invalidated as part of this, since the disk image will keep changing after
+// the stop and would no longer match it.
+func (vm *VirtualMachine) Stop() error {
+	if err := vm.VirtualMachine.Stop(); err != nil {
+		return err
+	}
+	vm.invalidateStateFile()
+	return nil
+}
+
+// invalidateStateFile removes the auto-restore snapshot next to the bundle,
+// if any. It's called once a snapshot has been consumed by an auto-restore
+// in Start, and on a normal Stop, so a future Start never restores from a
+// snapshot that no longer matches the disk image's contents.
+func (vm *VirtualMachine) invalidateStateFile() {
+	if statePath, ok := vm.stateFilePath(); ok {
+		_ = os.Remove(statePath)
+	}
+}
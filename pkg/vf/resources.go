@@ -0,0 +1,75 @@
+package vf
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+
+	"github.com/Code-Hex/vz/v3"
+	"golang.org/x/sys/unix"
+)
+
+// autoMemoryFraction is the portion of host physical memory handed to a
+// guest that asked for "auto" (--memory 0) sizing.
+const autoMemoryFraction = 0.25
+
+// ClampResources resolves the vCPU count and memory size to hand to
+// vz.NewVirtualMachineConfiguration. A requested value of 0 means "auto":
+// max(1, host_cpu-1) cores, and autoMemoryFraction of host RAM. The
+// resulting value, auto or not, is then clamped to the [minimum, maximum] range the
+// virtualization framework allows on this host, with the adjustment logged
+// so a guest that gets less than asked for doesn't fail deep inside
+// Objective-C validation with no explanation.
+func ClampResources(requestedVcpus uint, requestedMemoryBytes uint64) (uint, uint64, error) {
+	minVcpus := vz.VirtualMachineConfigurationMinimumAllowedCPUCount()
+	maxVcpus := vz.VirtualMachineConfigurationMaximumAllowedCPUCount()
+	minMemory := vz.VirtualMachineConfigurationMinimumAllowedMemorySize()
+	maxMemory := vz.VirtualMachineConfigurationMaximumAllowedMemorySize()
+
+	vcpus := requestedVcpus
+	if vcpus == 0 {
+		vcpus = uint(runtime.NumCPU() - 1)
+		if vcpus < 1 {
+			vcpus = 1
+		}
+	}
+	if clamped := clampUint(vcpus, uint(minVcpus), uint(maxVcpus)); clamped != vcpus {
+		log.Printf("requested %d vCPUs is outside the allowed range [%d, %d], using %d instead", vcpus, minVcpus, maxVcpus, clamped)
+		vcpus = clamped
+	}
+
+	memory := requestedMemoryBytes
+	if memory == 0 {
+		hostMemory, err := unix.SysctlUint64("hw.memsize")
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to determine host memory size: %w", err)
+		}
+		memory = uint64(float64(hostMemory) * autoMemoryFraction)
+	}
+	if clamped := clampUint64(memory, minMemory, maxMemory); clamped != memory {
+		log.Printf("requested %d bytes of memory is outside the allowed range [%d, %d], using %d instead", memory, minMemory, maxMemory, clamped)
+		memory = clamped
+	}
+
+	return vcpus, memory, nil
+}
+
+func clampUint(v, min, max uint) uint {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+func clampUint64(v, min, max uint64) uint64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
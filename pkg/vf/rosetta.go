@@ -0,0 +1,100 @@
+package vf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Code-Hex/vz/v3"
+)
+
+// RosettaShare exposes Apple's Rosetta translation layer to a Linux guest as
+// a virtiofs share tagged MountTag, letting the guest run x86_64 binaries
+// on Apple silicon.
+type RosettaShare struct {
+	MountTag string
+
+	// InstallIfMissing opts into installing Rosetta on demand (the
+	// --install-rosetta CLI flag) if it isn't already present on the host,
+	// instead of failing immediately.
+	InstallIfMissing bool
+
+	// InstallProgress, if set, receives the fraction complete (0..1) of an
+	// on-demand Rosetta install triggered by InstallIfMissing.
+	InstallProgress chan<- float64
+}
+
+func (dev *RosettaShare) AddToVirtualMachineConfig(vmConfig *VirtualMachineConfiguration) error {
+	if PlatformType != "linux" {
+		return fmt.Errorf("Rosetta shares are only supported for Linux guests, not %q", PlatformType)
+	}
+
+	switch vz.LinuxRosettaDirectoryShareAvailability() {
+	case vz.LinuxRosettaAvailabilityNotSupported:
+		return fmt.Errorf("Rosetta is not supported on this host")
+	case vz.LinuxRosettaAvailabilityNotInstalled:
+		if !dev.InstallIfMissing {
+			return fmt.Errorf("Rosetta is not installed; rerun vfkit with --install-rosetta")
+		}
+		if err := InstallRosetta(context.Background(), dev.InstallProgress); err != nil {
+			return fmt.Errorf("failed to install Rosetta: %w", err)
+		}
+	}
+
+	rosettaShare, err := vz.NewLinuxRosettaDirectoryShare()
+	if err != nil {
+		return fmt.Errorf("failed to create Rosetta directory share: %w", err)
+	}
+
+	shareConfig, err := vz.NewVirtioFileSystemDeviceConfiguration(dev.MountTag)
+	if err != nil {
+		return fmt.Errorf("failed to create Rosetta virtiofs device for tag %q: %w", dev.MountTag, err)
+	}
+	shareConfig.SetDirectoryShare(rosettaShare)
+
+	vmConfig.directorySharingDevicesConfiguration = append(vmConfig.directorySharingDevicesConfiguration, shareConfig)
+
+	return nil
+}
+
+// InstallRosetta installs Apple's Rosetta translation layer for Linux
+// guests if it isn't already present, reporting install progress as a
+// fraction between 0 and 1 on progress, which is closed when InstallRosetta
+// returns. It returns nil without installing anything if Rosetta is already
+// installed or is not supported on this host, and is cancellable through ctx.
+func InstallRosetta(ctx context.Context, progress chan<- float64) error {
+	if progress != nil {
+		defer close(progress)
+	}
+
+	if vz.LinuxRosettaDirectoryShareAvailability() != vz.LinuxRosettaAvailabilityNotInstalled {
+		return nil
+	}
+
+	installer, err := vz.NewLinuxRosettaDirectoryShareInstaller()
+	if err != nil {
+		return fmt.Errorf("failed to create Rosetta installer: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		installer.Cancel()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case frac, ok := <-installer.FractionCompleted():
+			if !ok {
+				return installer.Err()
+			}
+			if progress != nil {
+				select {
+				case progress <- frac:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+	}
+}
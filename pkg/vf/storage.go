@@ -0,0 +1,62 @@
+package vf
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Code-Hex/vz/v3"
+)
+
+// NBDStorageDevice attaches a virtio-blk disk backed by a remote Network
+// Block Device export (nbd://host:port/export) instead of a local disk
+// image, so a guest can boot off qemu-nbd or any other NBD server without
+// vfkit pre-attaching a local file.
+type NBDStorageDevice struct {
+	URL           string
+	Timeout       time.Duration
+	ReadOnly      bool
+	ForceReadOnly bool
+
+	// StateChange, if set, is called whenever the connection to the NBD
+	// server transitions between connected and disconnected, and on any
+	// connection error. It is left to the caller to set; nothing in this
+	// package sets it.
+	StateChange func(connected bool, err error)
+}
+
+func (dev *NBDStorageDevice) AddToVirtualMachineConfig(vmConfig *VirtualMachineConfiguration) error {
+	// vz only exposes a single "forced read-only" knob on the attachment: it
+	// rejects writes regardless of what the NBD server advertises. ReadOnly
+	// asks for the same behavior when the server can't be trusted to report
+	// its own export as read-only; ForceReadOnly is the explicit opt-in. Both
+	// map onto that one parameter.
+	forceReadOnly := dev.ReadOnly || dev.ForceReadOnly
+
+	attachment, err := vz.NewNetworkBlockDeviceStorageDeviceAttachment(
+		dev.URL,
+		dev.Timeout,
+		forceReadOnly,
+		vz.WithSynchronizationMode(vz.SynchronizationModeFull),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create NBD attachment for %s: %w", dev.URL, err)
+	}
+
+	if dev.StateChange != nil {
+		attachment.SetDidEncounterErrorHandler(func(err error) {
+			dev.StateChange(false, err)
+		})
+		attachment.SetConnectionStateDidChangeHandler(func(connected bool) {
+			dev.StateChange(connected, nil)
+		})
+	}
+
+	storageDeviceConfig, err := vz.NewVirtioBlockDeviceConfiguration(attachment)
+	if err != nil {
+		return fmt.Errorf("failed to create NBD storage device configuration for %s: %w", dev.URL, err)
+	}
+
+	vmConfig.storageDevicesConfiguration = append(vmConfig.storageDevicesConfiguration, storageDeviceConfig)
+
+	return nil
+}
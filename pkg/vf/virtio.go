@@ -0,0 +1,69 @@
+package vf
+
+import (
+	"fmt"
+
+	"github.com/Code-Hex/vz/v3"
+)
+
+// VirtioVsock configures a single vsock port the guest can use to talk to
+// the host. The virtualization framework only allows a single
+// VZVirtioSocketDeviceConfiguration per virtual machine, so AddToVirtualMachineConfig
+// merges every VirtioVsock onto the same underlying socket device
+// configuration instead of creating one device per port; wireVsockPorts
+// then turns each one into its own listener or outbound connection on that
+// single device once the vz.VirtualMachine has been created.
+type VirtioVsock struct {
+	Port   uint32
+	Listen bool
+}
+
+func (dev *VirtioVsock) AddToVirtualMachineConfig(vmConfig *VirtualMachineConfiguration) error {
+	for _, existing := range vmConfig.vsockPorts {
+		if existing.Port == dev.Port {
+			return fmt.Errorf("vsock port %d is already configured", dev.Port)
+		}
+	}
+
+	if len(vmConfig.SocketDevices()) == 0 {
+		socketDeviceConfig, err := vz.NewVirtioSocketDeviceConfiguration()
+		if err != nil {
+			return fmt.Errorf("failed to create virtio-vsock device: %w", err)
+		}
+		vmConfig.socketDevicesConfiguration = append(vmConfig.socketDevicesConfiguration, socketDeviceConfig)
+	}
+
+	vmConfig.vsockPorts = append(vmConfig.vsockPorts, *dev)
+
+	return nil
+}
+
+// wireVsockPorts sets up a listener or outbound connection for each
+// configured vsock port on vzVM's single socket device, once the
+// vz.VirtualMachine (and therefore the real VirtioSocketDevice) exists. It is
+// called from toVz, before the virtual machine is started.
+func wireVsockPorts(vzVM *vz.VirtualMachine, ports []VirtioVsock) error {
+	if len(ports) == 0 {
+		return nil
+	}
+
+	socketDevices := vzVM.SocketDevices()
+	if len(socketDevices) == 0 {
+		return fmt.Errorf("no vsock socket device present despite %d configured vsock port(s)", len(ports))
+	}
+	socketDevice := socketDevices[0]
+
+	for _, port := range ports {
+		if port.Listen {
+			if _, err := socketDevice.Listen(port.Port); err != nil {
+				return fmt.Errorf("failed to listen on vsock port %d: %w", port.Port, err)
+			}
+		} else {
+			if _, err := socketDevice.Connect(port.Port); err != nil {
+				return fmt.Errorf("failed to connect to vsock port %d: %w", port.Port, err)
+			}
+		}
+	}
+
+	return nil
+}
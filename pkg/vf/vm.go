@@ -9,6 +9,8 @@ package vf
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/Code-Hex/vz/v3"
 	"github.com/crc-org/vfkit/pkg/config"
@@ -61,10 +63,38 @@ func (vm *VirtualMachine) Start() error {
 		if err := vm.toVz(); err != nil {
 			return err
 		}
+		// resume from a previous suspend/SaveState snapshot rather than cold-booting,
+		// so users can iterate on a booted macOS VM without paying the boot cost again
+		if statePath, ok := vm.stateFilePath(); ok {
+			if _, err := os.Stat(statePath); err == nil {
+				if err := vm.RestoreState(statePath); err != nil {
+					return err
+				}
+				// the snapshot has now been consumed: remove it so a future
+				// Start doesn't restore stale state against a disk image
+				// that has since diverged
+				vm.invalidateStateFile()
+				// RestoreMachineStateFromPath leaves the VM paused, symmetric
+				// with SaveState pausing before it saves: resume so Start
+				// actually leaves the guest running, not just restored
+				return vm.Resume()
+			}
+		}
 	}
 	return vm.VirtualMachine.Start()
 }
 
+// stateFilePath returns the path vfkit looks at, next to the VM's bundle,
+// for a suspend/resume snapshot to auto-restore from on Start. Only macOS
+// guests have a bundle directory to look in.
+func (vm *VirtualMachine) stateFilePath() (string, bool) {
+	macosBootloader, ok := vm.vfConfig.config.Bootloader.(*config.MacOSBootloader)
+	if !ok {
+		return "", false
+	}
+	return filepath.Join(filepath.Dir(macosBootloader.AuxImagePath), macOSStateFileName), true
+}
+
 func (vm *VirtualMachine) toVz() error {
 	vzVMConfig, err := vm.vfConfig.toVz()
 	if err != nil {
@@ -76,6 +106,10 @@ func (vm *VirtualMachine) toVz() error {
 	}
 	vm.VirtualMachine = vzVM
 
+	if err := wireVsockPorts(vzVM, vm.vfConfig.vsockPorts); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -83,6 +117,14 @@ func (vm *VirtualMachine) Config() *config.VirtualMachine {
 	return vm.vfConfig.config
 }
 
+// ResolvedMemoryBytes returns the memory size, in bytes, that ClampResources
+// actually resolved the guest to, which may differ from vm.Config().Memory
+// when the requested value was "auto" or outside the platform's allowed
+// range.
+func (vm *VirtualMachine) ResolvedMemoryBytes() uint64 {
+	return vm.vfConfig.resolvedMemoryBytes
+}
+
 type VirtualMachineConfiguration struct {
 	*vz.VirtualMachineConfiguration                             // wrapper for Objective-C type
 	config                               *config.VirtualMachine // go-friendly virtual machine configuration definition
@@ -96,6 +138,18 @@ type VirtualMachineConfiguration struct {
 	serialPortsConfiguration             []*vz.VirtioConsoleDeviceSerialPortConfiguration
 	socketDevicesConfiguration           []vz.SocketDeviceConfiguration
 	consolePortsConfiguration            []*vz.VirtioConsolePortConfiguration
+	vsockPorts                           []VirtioVsock
+	resolvedMemoryBytes                  uint64
+}
+
+// SocketDevices returns the vsock socket devices currently configured on
+// this virtual machine. The virtualization framework only allows a single
+// VZVirtioSocketDeviceConfiguration per virtual machine, so this slice has
+// at most one entry: additional --device virtio-vsock entries are merged
+// onto it by VirtioVsock.AddToVirtualMachineConfig instead of appending a
+// second device.
+func (cfg *VirtualMachineConfiguration) SocketDevices() []vz.SocketDeviceConfiguration {
+	return cfg.socketDevicesConfiguration
 }
 
 func NewVirtualMachineConfiguration(vmConfig *config.VirtualMachine) (*VirtualMachineConfiguration, error) {
@@ -104,7 +158,19 @@ func NewVirtualMachineConfiguration(vmConfig *config.VirtualMachine) (*VirtualMa
 		return nil, err
 	}
 
-	vzVMConfig, err := vz.NewVirtualMachineConfiguration(vzBootloader, vmConfig.Vcpus, uint64(vmConfig.Memory.ToBytes()))
+	vcpus, memoryBytes, err := ClampResources(vmConfig.Vcpus, uint64(vmConfig.Memory.ToBytes()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve vCPU/memory configuration: %w", err)
+	}
+	// vmConfig.Vcpus is already a plain count, so it's safe to store the
+	// resolved value back: VirtualMachine.Config() (and the REST /vm/inspect
+	// endpoint) then report what the guest actually got, not what was
+	// requested. vmConfig.Memory is a config.Memory, whose own unit isn't
+	// necessarily bytes, so we don't round-trip resolved memory through it;
+	// resolvedMemoryBytes below is the authoritative resolved value instead.
+	vmConfig.Vcpus = vcpus
+
+	vzVMConfig, err := vz.NewVirtualMachineConfiguration(vzBootloader, vcpus, memoryBytes)
 	if err != nil {
 		return nil, err
 	}
@@ -112,6 +178,7 @@ func NewVirtualMachineConfiguration(vmConfig *config.VirtualMachine) (*VirtualMa
 	return &VirtualMachineConfiguration{
 		VirtualMachineConfiguration: vzVMConfig,
 		config:                      vmConfig,
+		resolvedMemoryBytes:         memoryBytes,
 	}, nil
 }
 
@@ -173,7 +240,8 @@ func (cfg *VirtualMachineConfiguration) toVz() (*vz.VirtualMachineConfiguration,
 		cfg.SetConsoleDevicesVirtualMachineConfiguration([]vz.ConsoleDeviceConfiguration{consoleDeviceConfiguration})
 	}
 
-	// len(cfg.socketDevicesConfiguration should be 0 or 1
+	// cfg.socketDevicesConfiguration has at most one entry: VirtioVsock.AddToVirtualMachineConfig
+	// merges every configured vsock port onto the same device instead of appending a second one.
 	// https://developer.apple.com/documentation/virtualization/vzvirtiosocketdeviceconfiguration?language=objc
 	cfg.SetSocketDevicesVirtualMachineConfiguration(cfg.socketDevicesConfiguration)
 